@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RecordConfig describes a single DNS record that the updater should keep in
+// sync with the host's external IP address.
+type RecordConfig struct {
+	Zone    string `json:"zone" yaml:"zone"`
+	Name    string `json:"name" yaml:"name"`
+	Type    string `json:"type" yaml:"type"` // "A" or "AAAA"
+	Proxied bool   `json:"proxied" yaml:"proxied"`
+	TTL     int    `json:"ttl" yaml:"ttl"` // seconds; 0 or 1 means "automatic"
+
+	// URLs lists the external-IP discovery endpoints to try, in order, for
+	// this record. The first one that succeeds is used; the rest act as
+	// fallbacks. If empty, the updater falls back to the global -url/-url6
+	// flags.
+	URLs []string `json:"urls,omitempty" yaml:"urls,omitempty"`
+}
+
+// Config is the top-level shape of the file loaded via -config. It lists all
+// the DNS records the updater is responsible for.
+type Config struct {
+	Records []RecordConfig `json:"records" yaml:"records"`
+}
+
+// LoadConfig reads and validates a JSON or YAML config file from path. The
+// format is chosen by file extension: .yaml/.yml for YAML, anything else
+// for JSON.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+	default:
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+	}
+
+	if len(cfg.Records) == 0 {
+		return nil, fmt.Errorf("config must list at least one record")
+	}
+
+	for i, r := range cfg.Records {
+		if r.Zone == "" || r.Name == "" {
+			return nil, fmt.Errorf("record %d: zone and name are required", i)
+		}
+		if r.Type != "A" && r.Type != "AAAA" {
+			return nil, fmt.Errorf("record %d (%s): type must be A or AAAA, got %q", i, r.Name, r.Type)
+		}
+	}
+
+	return &cfg, nil
+}
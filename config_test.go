@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		wantErr  bool
+	}{
+		{
+			name:     "valid JSON",
+			filename: "config.json",
+			content:  `{"records":[{"zone":"example.com","name":"home.example.com","type":"A"}]}`,
+		},
+		{
+			name:     "valid YAML",
+			filename: "config.yaml",
+			content: "records:\n" +
+				"  - zone: example.com\n" +
+				"    name: home.example.com\n" +
+				"    type: A\n",
+		},
+		{
+			name:     "valid yml extension",
+			filename: "config.yml",
+			content:  "records:\n  - zone: example.com\n    name: home.example.com\n    type: AAAA\n",
+		},
+		{
+			name:     "no records",
+			filename: "config.json",
+			content:  `{"records":[]}`,
+			wantErr:  true,
+		},
+		{
+			name:     "missing zone",
+			filename: "config.json",
+			content:  `{"records":[{"name":"home.example.com","type":"A"}]}`,
+			wantErr:  true,
+		},
+		{
+			name:     "invalid type",
+			filename: "config.json",
+			content:  `{"records":[{"zone":"example.com","name":"home.example.com","type":"CNAME"}]}`,
+			wantErr:  true,
+		},
+		{
+			name:     "malformed JSON",
+			filename: "config.json",
+			content:  `{"records":`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.filename)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("failed to write test config: %v", err)
+			}
+
+			cfg, err := LoadConfig(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("LoadConfig(%q) = %v, want error", tt.filename, cfg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig(%q) failed: %v", tt.filename, err)
+			}
+			if len(cfg.Records) != 1 {
+				t.Fatalf("len(cfg.Records) = %d, want 1", len(cfg.Records))
+			}
+		})
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("LoadConfig() = nil, want error for missing file")
+	}
+}
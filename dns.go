@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	log "log/slog"
+	"net/netip"
+	"sync"
+	"time"
+
+	cloudflare "github.com/cloudflare/cloudflare-go"
+)
+
+// cacheEntry is the last IP DNSUpdater wrote for a record, and how long it
+// can be trusted before the next write reconfirms it with Cloudflare. A
+// zero expires means the entry never goes stale.
+type cacheEntry struct {
+	addr    netip.Addr
+	expires time.Time
+}
+
+func (e cacheEntry) valid(ip netip.Addr) bool {
+	return e.addr == ip && (e.expires.IsZero() || time.Now().Before(e.expires))
+}
+
+// DNSUpdater keeps a set of configured Cloudflare DNS records in sync with
+// the host's external IP address(es).
+type DNSUpdater struct {
+	api      *cloudflare.API
+	records  []RecordConfig
+	cache    map[string]cacheEntry // keyed by recordKey
+	cacheTTL time.Duration
+	ntfy     Notifier
+	sync.Mutex
+}
+
+// NewDNSUpdater creates a DNSUpdater that manages the given records.
+// cacheTTL bounds how long the in-memory cache of last-written addresses is
+// trusted before a write reconfirms it with Cloudflare; 0 means forever.
+func NewDNSUpdater(token string, records []RecordConfig, n Notifier, cacheTTL time.Duration) (*DNSUpdater, error) {
+	api, err := cloudflare.NewWithAPIToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return &DNSUpdater{
+		api:      api,
+		records:  records,
+		cache:    make(map[string]cacheEntry),
+		cacheTTL: cacheTTL,
+		ntfy:     n,
+	}, nil
+}
+
+// Reconcile populates the cache from the current state of each configured
+// record in Cloudflare, so a restart doesn't blindly rewrite records that
+// already hold the right address.
+func (d *DNSUpdater) Reconcile() error {
+	d.Lock()
+	defer d.Unlock()
+
+	ctx := context.Background()
+	var errs []error
+	for _, rec := range d.records {
+		zoneID, err := d.api.ZoneIDByName(rec.Zone)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to find zone %s: %w", rec.Name, rec.Zone, err))
+			continue
+		}
+
+		recs, _, err := d.api.ListDNSRecords(ctx, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{
+			Type: rec.Type,
+			Name: rec.Name,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to list records: %w", rec.Name, err))
+			continue
+		}
+		if len(recs) == 0 {
+			continue
+		}
+
+		ip, err := netip.ParseAddr(recs[0].Content)
+		if err != nil {
+			continue
+		}
+
+		d.cache[recordKey(rec)] = d.newCacheEntry(ip)
+		log.Debug("Reconciled cache from Cloudflare", "record", rec.Name, "ip", ip)
+	}
+
+	return errors.Join(errs...)
+}
+
+// DeleteAll removes every configured record from Cloudflare. Used by
+// -delete-on-stop so an ephemeral instance doesn't leave a stale record
+// pointing at a released IP.
+func (d *DNSUpdater) DeleteAll() error {
+	d.Lock()
+	defer d.Unlock()
+
+	ctx := context.Background()
+	var errs []error
+	for _, rec := range d.records {
+		zoneID, err := d.api.ZoneIDByName(rec.Zone)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to find zone %s: %w", rec.Name, rec.Zone, err))
+			continue
+		}
+
+		id, err := findDNSRecordID(ctx, d.api, zoneID, rec.Name, rec.Type)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to find record: %w", rec.Name, err))
+			continue
+		}
+		if id == "" {
+			continue
+		}
+
+		if err := d.api.DeleteDNSRecord(ctx, cloudflare.ZoneIdentifier(zoneID), id); err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to delete record: %w", rec.Name, err))
+			continue
+		}
+		delete(d.cache, recordKey(rec))
+		log.Info("Record deleted.", "record", rec.Name, "type", rec.Type)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (d *DNSUpdater) newCacheEntry(ip netip.Addr) cacheEntry {
+	entry := cacheEntry{addr: ip}
+	if d.cacheTTL > 0 {
+		entry.expires = time.Now().Add(d.cacheTTL)
+	}
+	return entry
+}
+
+// Records returns the configured records of the given type ("A" or "AAAA").
+func (d *DNSUpdater) Records(recordType string) []RecordConfig {
+	var out []RecordConfig
+	for _, r := range d.records {
+		if r.Type == recordType {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// MatchingRecords returns the configured records named name, narrowed to
+// zone if zone is non-empty. Used by the webhook handler to target the
+// record(s) a caller asks for instead of every configured record.
+func (d *DNSUpdater) MatchingRecords(zone, name string) []RecordConfig {
+	var out []RecordConfig
+	for _, r := range d.records {
+		if r.Name != name {
+			continue
+		}
+		if zone != "" && r.Zone != zone {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// Update brings a single configured record in line with ip, skipping the
+// Cloudflare API call entirely if the address hasn't changed since the last
+// successful update and that cache entry hasn't expired (see cacheTTL). The mutex only guards the cache map; the Cloudflare
+// round-trip itself runs unlocked so updates to independent records (and
+// independent goroutines in pollAndUpdate) don't serialize behind it.
+func (d *DNSUpdater) Update(rec RecordConfig, ip netip.Addr) error {
+	key := recordKey(rec)
+
+	d.Lock()
+	cached, ok := d.cache[key]
+	d.Unlock()
+	if ok && cached.valid(ip) {
+		log.Debug("Address unchanged", "record", rec.Name, "type", rec.Type, "ip", ip)
+		return nil
+	}
+
+	if err := d.updateRecord(rec, ip); err != nil {
+		return fmt.Errorf("failed to update %s record: %w", rec.Type, err)
+	}
+
+	d.Lock()
+	d.cache[key] = d.newCacheEntry(ip)
+	d.Unlock()
+
+	log.Info("New address stored", "record", rec.Name, "type", rec.Type, "ip", ip)
+	d.ntfy.NotifySuccessUpdateIP(ip)
+	return nil
+}
+
+// updateRecord writes ip, Proxied and TTL to the record in Cloudflare,
+// creating it first if it doesn't exist yet.
+func (d *DNSUpdater) updateRecord(rec RecordConfig, ip netip.Addr) error {
+	ctx := context.Background()
+
+	zoneID, err := d.api.ZoneIDByName(rec.Zone)
+	if err != nil {
+		return fmt.Errorf("failed to find zone %s: %w", rec.Zone, err)
+	}
+
+	id, err := findDNSRecordID(ctx, d.api, zoneID, rec.Name, rec.Type)
+	if err != nil {
+		return fmt.Errorf("failed to find %s record %s: %w", rec.Type, rec.Name, err)
+	}
+
+	proxied := rec.Proxied
+	ttl := rec.TTL
+	if ttl == 0 {
+		ttl = 1 // automatic
+	}
+
+	if id == "" {
+		created, err := d.api.CreateDNSRecord(ctx, cloudflare.ZoneIdentifier(zoneID), cloudflare.CreateDNSRecordParams{
+			Type:    rec.Type,
+			Name:    rec.Name,
+			Content: ip.String(),
+			Proxied: &proxied,
+			TTL:     ttl,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create record: %w", err)
+		}
+		log.Info("Record created.", "recordType", created.Type, "name", rec.Name, "content", created.Content)
+		return nil
+	}
+
+	updated, err := d.api.UpdateDNSRecord(ctx, cloudflare.ZoneIdentifier(zoneID), cloudflare.UpdateDNSRecordParams{
+		ID:      id,
+		Type:    rec.Type,
+		Content: ip.String(),
+		Proxied: &proxied,
+		TTL:     ttl,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update record: %w", err)
+	}
+	log.Info("Record updated successfully.", "recordType", updated.Type, "name", rec.Name, "content", updated.Content)
+
+	return nil
+}
+
+// findDNSRecordID returns the ID of the DNS record matching name and
+// recordType in the given zone, or "" if no such record exists yet.
+func findDNSRecordID(ctx context.Context, api *cloudflare.API, zoneID, name, recordType string) (string, error) {
+	recs, _, err := api.ListDNSRecords(ctx, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{
+		Type: recordType,
+		Name: name,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(recs) == 0 {
+		return "", nil
+	}
+
+	log.Debug("Found DNS record", "type", recordType, "name", name, "id", recs[0].ID, "content", recs[0].Content)
+	return recs[0].ID, nil
+}
+
+// recordKey uniquely identifies a configured record for caching purposes.
+func recordKey(r RecordConfig) string {
+	return r.Zone + "/" + r.Name + "/" + r.Type
+}
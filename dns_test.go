@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestCacheEntryValid(t *testing.T) {
+	ip := mustParseAddr(t, "1.2.3.4")
+	other := mustParseAddr(t, "5.6.7.8")
+
+	tests := []struct {
+		name  string
+		entry cacheEntry
+		ip    netip.Addr
+		want  bool
+	}{
+		{"matching address, no expiry", cacheEntry{addr: ip}, ip, true},
+		{"different address, no expiry", cacheEntry{addr: ip}, other, false},
+		{"matching address, not yet expired", cacheEntry{addr: ip, expires: time.Now().Add(time.Hour)}, ip, true},
+		{"matching address, expired", cacheEntry{addr: ip, expires: time.Now().Add(-time.Hour)}, ip, false},
+		{"different address, not yet expired", cacheEntry{addr: ip, expires: time.Now().Add(time.Hour)}, other, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.valid(tt.ip); got != tt.want {
+				t.Errorf("valid(%v) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCacheEntry(t *testing.T) {
+	ip := mustParseAddr(t, "1.2.3.4")
+
+	d := &DNSUpdater{}
+	entry := d.newCacheEntry(ip)
+	if !entry.expires.IsZero() {
+		t.Errorf("expires = %v, want zero with cacheTTL 0", entry.expires)
+	}
+	if !entry.valid(ip) {
+		t.Error("entry should be valid for the same IP with no TTL")
+	}
+
+	d.cacheTTL = time.Hour
+	entry = d.newCacheEntry(ip)
+	if entry.expires.IsZero() {
+		t.Error("expires should be set when cacheTTL is non-zero")
+	}
+	if !entry.valid(ip) {
+		t.Error("entry should be valid immediately after creation")
+	}
+}
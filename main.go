@@ -2,18 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
 	log "log/slog"
 	"net/http"
 	"net/netip"
 	"os"
+	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
-	cloudflare "github.com/cloudflare/cloudflare-go"
 	group "github.com/oklog/run"
 )
 
@@ -21,122 +22,205 @@ func main() {
 	bindAddr := flag.String("addr", ":8081", "address of the http server")
 	polling := flag.Bool("polling", false, "use periodic polling in addition to webhook")
 	interval := flag.Int("interval", 60, "interval in seconds for polling (only used if polling is enabled)")
-	ntfyAddr := flag.String("ntfy", "", "ntfy.sh token to send notifications to when the address changes")
-	zone := flag.String("zone", "", "Cloudflare zone to update (required when polling is enabled)")
+	var notifyURLs stringList
+	flag.Var(&notifyURLs, "notify", "notifier URL to send update notifications to (ntfy://, discord://, slack://, gotify://, smtp(s)://, https://); may be repeated")
+	notifyGrace := flag.Duration("notify-grace", 30*time.Minute, "grace period before repeating a failure notification")
+	var monitorURLs stringList
+	flag.Var(&monitorURLs, "monitor", "health-check ping URL, e.g. https://hc-ping.com/<uuid>; may be repeated")
+	monitorTimeout := flag.Duration("monitor-timeout", 10*time.Second, "timeout for a single monitor ping")
+	monitorRetries := flag.Int("monitor-retries", 2, "number of retries for a failed monitor ping")
+	deleteOnStop := flag.Bool("delete-on-stop", false, "delete every configured record from Cloudflare on SIGINT/SIGTERM")
+	cacheTTL := flag.Duration("cache-ttl", 0, "how long to trust the in-memory address cache before reconfirming a write with Cloudflare (0 = forever)")
+	configPath := flag.String("config", "", "path to JSON or YAML config file listing the DNS records to manage (required)")
 	debug := flag.Bool("debug", false, "enable debug logging")
-	queryURL := flag.String("url", "https://api.ipify.org", "URL to query for the external IP address")
-	queryURL6 := flag.String("url6", "https://api6.ipify.org", "URL to query for the external IPv6 address")
+	queryURL := flag.String("url", "https://api.ipify.org", "default URL to query for the external IP address, used by records without their own urls")
+	queryURL6 := flag.String("url6", "https://api6.ipify.org", "default URL to query for the external IPv6 address, used by records without their own urls")
 	flag.Parse()
 
 	if *debug {
 		log.SetLogLoggerLevel(log.LevelDebug)
 	}
 
-	var ntfy Notifier
-	if *ntfyAddr != "" {
-		ntfy = &NtfyNotifier{token: *ntfyAddr, grace: 30 * time.Minute}
-	} else {
-		ntfy = &FakeNotifier{}
+	if *configPath == "" {
+		log.Error("Config file must be specified via -config")
+		os.Exit(1)
+	}
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		log.Error("Failed to load config", "error", err)
+		os.Exit(1)
 	}
 
-	var actors group.Group
-	// handle user signals, like Ctrl+C, to stop all actors
-	actors.Add(group.SignalHandler(context.Background(), os.Interrupt, syscall.SIGTERM))
+	ntfy, err := buildNotifier(notifyURLs, *notifyGrace)
+	if err != nil {
+		log.Error("Failed to configure notifiers", "error", err)
+		os.Exit(1)
+	}
+	monitor := buildMonitor(monitorURLs, *monitorTimeout, *monitorRetries)
 
-	updater, err := NewDNSUpdater(os.Getenv("CLOUDFLARE_API_TOKEN"), ntfy)
+	updater, err := NewDNSUpdater(os.Getenv("CLOUDFLARE_API_TOKEN"), cfg.Records, ntfy, *cacheTTL)
 	if err != nil {
 		log.Error("Failed to create DNS updater", "error", err)
 		os.Exit(1)
 	}
+	if err := updater.Reconcile(); err != nil {
+		log.Warn("Failed to reconcile cache from Cloudflare", "error", err)
+	}
+
+	// stopPoll tells the poller to stop; pollStopped is closed once it has.
+	// stopServer/serverStopped do the same for the webhook listener.
+	// signalHandler triggers both and waits for both to finish before
+	// deleting records, so neither an in-flight poll tick nor a webhook
+	// request already being handled can race DeleteAll and recreate a
+	// record it just removed. With polling disabled there's nothing to
+	// stop, so pollStopped starts out already closed.
+	stopPoll := make(chan struct{})
+	var stopPollOnce sync.Once
+	closeStopPoll := func() { stopPollOnce.Do(func() { close(stopPoll) }) }
+	pollStopped := make(chan struct{})
+	if !*polling {
+		close(pollStopped)
+	}
 
 	srv := &http.Server{Handler: NewServer(updater), Addr: *bindAddr}
+	serverStopped := make(chan struct{})
+	var stopServerOnce sync.Once
+	// stopServer gracefully shuts the webhook listener down, waiting for any
+	// in-flight request (e.g. one already inside DNSUpdater.Update) to
+	// finish rather than cutting it off mid-handling.
+	stopServer := func() {
+		stopServerOnce.Do(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(ctx)
+		})
+	}
+
+	var actors group.Group
+	// handle user signals, like Ctrl+C, to stop all actors; also deletes
+	// records on the way out if -delete-on-stop is set
+	actors.Add(signalHandler(updater, *deleteOnStop, closeStopPoll, pollStopped, stopServer, serverStopped))
+
 	actors.Add(func() error {
 		log.Info("Server started", "addr", srv.Addr)
-		if err = srv.ListenAndServe(); err != nil {
+		defer close(serverStopped)
+		if err = srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			return fmt.Errorf("REST Server failed: %w", err)
 		}
 		return nil
 	}, func(error) {
-		_ = srv.Close()
+		stopServer()
 	})
 
 	// start polling if enabled
 	if *polling {
-		if *zone == "" {
-			log.Error("Zone must be specified when polling is enabled")
-			os.Exit(1)
-		}
-		done := make(chan struct{})
 		actors.Add(func() error {
-			return pollAndUpdate(done, updater, ntfy, *queryURL, *queryURL6, *interval, *zone)
-		}, func(error) { close(done) })
+			defer close(pollStopped)
+			return pollAndUpdate(stopPoll, updater, ntfy, monitor, *queryURL, *queryURL6, *interval)
+		}, func(error) { closeStopPoll() })
 	}
 
 	if err = actors.Run(); err != nil {
 		log.Error("Error running actors", "error", err)
+		monitor.ExitStatus(1, err.Error())
 		os.Exit(1)
 	}
+	monitor.ExitStatus(0, "stopped")
 }
 
-type DNSUpdater struct {
-	api   *cloudflare.API
-	addr  netip.Addr
-	addr6 netip.Addr
-	ntfy  Notifier
-	sync.Mutex
-}
+// stringList accumulates repeated occurrences of a flag into a slice.
+type stringList []string
 
-func NewDNSUpdater(token string, n Notifier) (*DNSUpdater, error) {
-	api, err := cloudflare.NewWithAPIToken(token)
-	if err != nil {
-		return nil, err
-	}
-	return &DNSUpdater{
-		api:  api,
-		ntfy: n,
-	}, nil
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
 }
 
-func (d *DNSUpdater) UpdateIP4(ip netip.Addr, zone string) error {
-	return d.updateIP(ip, zone, "A", &d.addr)
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
 }
 
-func (d *DNSUpdater) UpdateIP6(ip netip.Addr, zone string) error {
-	return d.updateIP(ip, zone, "AAAA", &d.addr6)
-}
+// buildNotifier parses each notifier URL, wraps its Sender with grace-period
+// deduplication, and fans them out via a MultiNotifier. With no URLs
+// configured, notifications are silently discarded.
+func buildNotifier(urls []string, grace time.Duration) (Notifier, error) {
+	if len(urls) == 0 {
+		return FakeNotifier{}, nil
+	}
+
+	notifiers := make(MultiNotifier, 0, len(urls))
+	for _, u := range urls {
+		sender, err := ParseNotifier(u)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, NewGracefulNotifier(sender, grace))
+	}
 
-func (d *DNSUpdater) updateIP(ip netip.Addr, zone string, recordType string, addrPtr *netip.Addr) error {
-	d.Lock()
-	defer d.Unlock()
+	return notifiers, nil
+}
 
-	// Check if the address has actually changed
-	if *addrPtr == ip {
-		log.Debug("Address unchanged", "type", recordType, "ip", ip)
-		return nil
+// buildMonitor wraps a health-check ping URL per entry into a MultiMonitor.
+// With no URLs configured, pings are silently discarded.
+func buildMonitor(urls []string, timeout time.Duration, retries int) Monitor {
+	if len(urls) == 0 {
+		return FakeMonitor{}
 	}
 
-	err := updateRecord(zone, ip.String(), recordType)
-	if err != nil {
-		return fmt.Errorf("failed to update %s record: %w", recordType, err)
+	monitors := make(MultiMonitor, 0, len(urls))
+	for _, u := range urls {
+		monitors = append(monitors, NewHTTPPingMonitor(u, timeout, retries))
 	}
+	return monitors
+}
 
-	// Update the stored address
-	*addrPtr = ip
-	log.Info("New address stored", "type", recordType, "ip", ip)
+// signalHandler waits for SIGINT/SIGTERM and, if deleteOnStop is set,
+// removes every configured record from Cloudflare before the actor returns
+// so the rest of actors.Group can shut down behind it. Before deleting, it
+// calls stopPoll/stopServer to stop the poller and webhook listener and
+// waits for pollStopped/serverStopped, so a poll tick or webhook request
+// that's already mid-flight can't race DeleteAll and recreate a record it
+// just removed.
+func signalHandler(updater *DNSUpdater, deleteOnStop bool, stopPoll func(), pollStopped <-chan struct{}, stopServer func(), serverStopped <-chan struct{}) (execute func() error, interrupt func(error)) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	cancel := make(chan struct{})
 
-	// Notify about the successful update
-	d.ntfy.NotifySuccessUpdateIP(ip)
-	return nil
+	execute = func() error {
+		select {
+		case s := <-sig:
+			log.Info("Received signal.", "signal", s)
+		case <-cancel:
+			return nil
+		}
+
+		if deleteOnStop {
+			stopServer()
+			<-serverStopped
+			stopPoll()
+			<-pollStopped
+			if err := updater.DeleteAll(); err != nil {
+				log.Error("Failed to delete records on stop", "error", err)
+			}
+		}
+		return nil
+	}
+	interrupt = func(error) {
+		close(cancel)
+	}
+	return execute, interrupt
 }
 
-type Notifier interface {
-	NotifyFailedGetIP(error)
-	NotifyFailedUpdateIP(error)
-	NotifySuccessGetIP()
-	NotifySuccessUpdateIP(netip.Addr)
+// defaultURLs returns the external-IP discovery endpoints to use for a
+// record that doesn't configure its own, based on its record type.
+func defaultURLs(recordType, url4, url6 string) []string {
+	if recordType == "AAAA" {
+		return []string{url6}
+	}
+	return []string{url4}
 }
 
-func pollAndUpdate(done <-chan struct{}, updater *DNSUpdater, ntfy Notifier, url, url6 string, interval int, zone string) error {
+func pollAndUpdate(done <-chan struct{}, updater *DNSUpdater, ntfy Notifier, mon Monitor, url, url6 string, interval int) error {
 	ticker := time.NewTicker(time.Duration(interval) * time.Second)
 	defer ticker.Stop()
 	for {
@@ -144,74 +228,60 @@ func pollAndUpdate(done <-chan struct{}, updater *DNSUpdater, ntfy Notifier, url
 		case <-done:
 			return nil
 		case <-ticker.C:
+			mon.Start()
+
 			var wg sync.WaitGroup
-			var addrV4, addrV6 netip.Addr
-			var errV4, errV6 error
-
-			wg.Add(2)
-
-			// Fetch IPv4
-			go func() {
-				defer wg.Done()
-				addrV4, errV4 = GetExternalIP(url)
-				if errV4 != nil {
-					log.Error("Failed to get external IPv4", "error", errV4)
-					ntfy.NotifyFailedGetIP(fmt.Errorf("IPv4: %w", errV4))
-				} else {
-					log.Debug("Got external IPv4", "ip", addrV4)
-					ntfy.NotifySuccessGetIP()
-				}
-			}()
-
-			// Fetch IPv6
-			go func() {
-				defer wg.Done()
-				addrV6, errV6 = GetExternalIP(url6)
-				if errV6 != nil {
-					log.Error("Failed to get external IPv6", "error", errV6)
-					ntfy.NotifyFailedGetIP(fmt.Errorf("IPv6: %w", errV6))
-				} else {
-					log.Debug("Got external IPv6", "ip", addrV6)
-					ntfy.NotifySuccessGetIP()
-				}
-			}()
+			for _, rec := range updater.records {
+				wg.Add(1)
+				go func(rec RecordConfig) {
+					defer wg.Done()
 
-			wg.Wait()
+					urls := rec.URLs
+					if len(urls) == 0 {
+						urls = defaultURLs(rec.Type, url, url6)
+					}
 
-			// Update IPv4 if fetched successfully
-			if errV4 == nil && addrV4.IsValid() {
-				if err := updater.UpdateIP4(addrV4, zone); err != nil {
-					log.Error("Failed to update IPv4", "error", err)
-					ntfy.NotifyFailedUpdateIP(fmt.Errorf("IPv4: %w", err))
-				}
-			}
+					addr, err := GetExternalIP(urls)
+					if err != nil {
+						log.Error("Failed to get external IP", "record", rec.Name, "error", err)
+						ntfy.NotifyFailedGetIP(fmt.Errorf("%s: %w", rec.Name, err))
+						mon.Failure(err)
+						return
+					}
+					log.Debug("Got external IP", "record", rec.Name, "ip", addr)
+					ntfy.NotifySuccessGetIP()
 
-			// Update IPv6 if fetched successfully
-			if errV6 == nil && addrV6.IsValid() {
-				if err := updater.UpdateIP6(addrV6, zone); err != nil {
-					log.Error("Failed to update IPv6", "error", err)
-					ntfy.NotifyFailedUpdateIP(fmt.Errorf("IPv6: %w", err))
-				}
+					if err := updater.Update(rec, addr); err != nil {
+						log.Error("Failed to update record", "record", rec.Name, "error", err)
+						ntfy.NotifyFailedUpdateIP(fmt.Errorf("%s: %w", rec.Name, err))
+						mon.Failure(err)
+						return
+					}
+					mon.Success()
+				}(rec)
 			}
+			wg.Wait()
 		}
 	}
 }
 
-// GetExternalIP fetches the external IP address and returns it as a netip.Addr.
-func GetExternalIP(url string) (netip.Addr, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return netip.Addr{}, err
-	}
-	defer resp.Body.Close()
-	ipBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return netip.Addr{}, err
-	}
-	ipStr := string(ipBytes)
-	ip, err := netip.ParseAddr(ipStr)
-	if err != nil {
-		return netip.Addr{}, fmt.Errorf("invalid IP address format: %s", ipStr)
+// GetExternalIP fetches the external IP address from the first URL in urls
+// that succeeds, falling back to the next one on failure. Each URL's scheme
+// selects the Resolver used to query it; see NewResolver.
+func GetExternalIP(urls []string) (netip.Addr, error) {
+	var errs []error
+	for _, url := range urls {
+		resolver, err := NewResolver(url)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+			continue
+		}
+		ip, err := resolver.Resolve()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+			continue
+		}
+		return ip, nil
 	}
-	return ip, nil
+	return netip.Addr{}, fmt.Errorf("all IP providers failed: %w", errors.Join(errs...))
 }
@@ -0,0 +1,56 @@
+package main
+
+// Monitor is pinged about the lifecycle of the polling loop so an external
+// heartbeat service (e.g. healthchecks.io) can detect a silently-dead
+// updater, rather than operators having to rely on "no notifications" to
+// mean "healthy".
+type Monitor interface {
+	Start()
+	Success()
+	Failure(error)
+	ExitStatus(code int, msg string)
+}
+
+// MultiMonitor fans every ping out to a set of Monitors, e.g. one per
+// -monitor URL passed on the command line.
+type MultiMonitor []Monitor
+
+func (m MultiMonitor) Start() {
+	for _, mon := range m {
+		mon.Start()
+	}
+}
+
+func (m MultiMonitor) Success() {
+	for _, mon := range m {
+		mon.Success()
+	}
+}
+
+func (m MultiMonitor) Failure(err error) {
+	for _, mon := range m {
+		mon.Failure(err)
+	}
+}
+
+func (m MultiMonitor) ExitStatus(code int, msg string) {
+	for _, mon := range m {
+		mon.ExitStatus(code, msg)
+	}
+}
+
+// FakeMonitor discards every ping. It's the default when no -monitor flags
+// are given.
+type FakeMonitor struct{}
+
+func (f FakeMonitor) Start() {
+}
+
+func (f FakeMonitor) Success() {
+}
+
+func (f FakeMonitor) Failure(_ error) {
+}
+
+func (f FakeMonitor) ExitStatus(_ int, _ string) {
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	log "log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPPingMonitor pings a healthchecks.io-style endpoint: GET the base URL
+// on success, <base>/fail on failure, <base>/start before each polling
+// cycle, and <base>/<exit-code> on exit.
+type HTTPPingMonitor struct {
+	BaseURL string
+	Retries int
+
+	client *http.Client
+}
+
+// NewHTTPPingMonitor creates a monitor pinging baseURL, e.g.
+// https://hc-ping.com/<uuid>.
+func NewHTTPPingMonitor(baseURL string, timeout time.Duration, retries int) *HTTPPingMonitor {
+	return &HTTPPingMonitor{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Retries: retries,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (m *HTTPPingMonitor) Start() {
+	m.ping(m.BaseURL + "/start")
+}
+
+func (m *HTTPPingMonitor) Success() {
+	m.ping(m.BaseURL)
+}
+
+func (m *HTTPPingMonitor) Failure(_ error) {
+	m.ping(m.BaseURL + "/fail")
+}
+
+func (m *HTTPPingMonitor) ExitStatus(code int, _ string) {
+	m.ping(fmt.Sprintf("%s/%d", m.BaseURL, code))
+}
+
+// ping GETs url, retrying up to m.Retries times on failure.
+func (m *HTTPPingMonitor) ping(url string) {
+	var err error
+	for attempt := 0; attempt <= m.Retries; attempt++ {
+		var resp *http.Response
+		resp, err = m.client.Get(url)
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return
+		}
+		err = fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	log.Warn("Failed to ping monitor", "url", url, "error", err)
+}
@@ -1,89 +1,126 @@
 package main
 
 import (
-	"fmt"
-	"io"
-	"net/http"
 	"net/netip"
-	"strings"
+	"sync"
 	"time"
 )
 
-// NtfyNotifier sends notifications to ntfy.sh. GetIP or UpdateIP has to fail for more than grace period to send a notification.
-// If the failure continues, the notification will be sent again after the grace period.
-type NtfyNotifier struct {
-	token                       string
-	grace                       time.Duration // grace period for notifications about failure
-	lastGetIP, lastUpdateIP     time.Time     // last successful getIP and updateIP
-	failedGetIP, failedUpdateIP time.Time     // last notification of failure
+// Notifier is told about the outcome of IP lookups and record updates so it
+// can alert an operator.
+type Notifier interface {
+	NotifyFailedGetIP(error)
+	NotifyFailedUpdateIP(error)
+	NotifySuccessGetIP()
+	NotifySuccessUpdateIP(netip.Addr)
 }
 
-func (n *NtfyNotifier) NotifyFailedGetIP(err error) {
+// Sender delivers a single notification message through one backend (ntfy,
+// Discord, Slack, ...). level is a coarse severity ("info" or "warning")
+// that a Sender may map to its own presentation (emoji, color, priority).
+type Sender interface {
+	Send(level, message string) error
+}
+
+// GracefulNotifier adapts a Sender into a Notifier by adding grace-period
+// deduplication: once a failure notification has gone out, another one of
+// the same kind won't be sent until grace has elapsed, and a single
+// "repaired" notification is sent on recovery. This logic used to be
+// duplicated in every backend; now every backend gets it for free by being
+// wrapped in a GracefulNotifier.
+type GracefulNotifier struct {
+	sender Sender
+	grace  time.Duration
+
+	mu                          sync.Mutex
+	lastGetIP, lastUpdateIP     time.Time // last successful getIP and updateIP
+	failedGetIP, failedUpdateIP time.Time // last notification of failure
+}
+
+// NewGracefulNotifier wraps sender so that failure notifications are
+// deduplicated within grace.
+func NewGracefulNotifier(sender Sender, grace time.Duration) *GracefulNotifier {
+	return &GracefulNotifier{sender: sender, grace: grace}
+}
+
+func (n *GracefulNotifier) NotifyFailedGetIP(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
 	if time.Since(n.lastGetIP) > n.grace && time.Since(n.failedGetIP) > n.grace {
-		_ = n.Notify("warning", "Failed to get IP address: "+err.Error())
+		_ = n.sender.Send("warning", "Failed to get IP address: "+err.Error())
 		n.failedGetIP = time.Now()
 	}
 }
 
-func (n *NtfyNotifier) NotifyFailedUpdateIP(err error) {
+func (n *GracefulNotifier) NotifyFailedUpdateIP(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
 	if time.Since(n.lastUpdateIP) > n.grace && time.Since(n.failedUpdateIP) > n.grace {
-		_ = n.Notify("warning", "Failed to update IP address: "+err.Error())
+		_ = n.sender.Send("warning", "Failed to update IP address: "+err.Error())
 		n.failedUpdateIP = time.Now()
 	}
 }
 
-func (n *NtfyNotifier) NotifySuccessGetIP() {
+func (n *GracefulNotifier) NotifySuccessGetIP() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
 	defer func() { n.lastGetIP = time.Now() }()
 	if time.Since(n.lastGetIP) > n.grace {
-		_ = n.Notify("globe_with_meridians", "Repaired: Get IP address")
-		return
+		_ = n.sender.Send("info", "Repaired: Get IP address")
 	}
 }
 
-func (n *NtfyNotifier) NotifySuccessUpdateIP(ip netip.Addr) {
+func (n *GracefulNotifier) NotifySuccessUpdateIP(ip netip.Addr) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
 	defer func() { n.lastUpdateIP = time.Now() }()
 	if time.Since(n.lastUpdateIP) > time.Since(n.failedUpdateIP) {
-		_ = n.Notify("globe_with_meridians", "Repaired: IP address updated to "+ip.String())
+		_ = n.sender.Send("info", "Repaired: IP address updated to "+ip.String())
 		return
 	}
-	_ = n.Notify("globe_with_meridians", "New IP address: "+ip.String())
+	_ = n.sender.Send("info", "New IP address: "+ip.String())
 }
 
-func (n *NtfyNotifier) Notify(tags, msg string) error {
-	url := fmt.Sprintf("https://ntfy.sh/%s", n.token)
-	req, _ := http.NewRequest("POST", url,
-		strings.NewReader(msg))
-	req.Header.Set("Title", "DNS Updater")
-	req.Header.Set("Tags", tags)
+// MultiNotifier fans every notification out to a set of Notifiers, e.g. one
+// per -notify URL passed on the command line.
+type MultiNotifier []Notifier
 
-	r, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send notification: %w", err)
+func (m MultiNotifier) NotifyFailedGetIP(err error) {
+	for _, n := range m {
+		n.NotifyFailedGetIP(err)
 	}
-	defer r.Body.Close()
-	if r.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(r.Body)
-		return fmt.Errorf("failed to send notification: %s - %s", r.Status, body)
+}
+
+func (m MultiNotifier) NotifyFailedUpdateIP(err error) {
+	for _, n := range m {
+		n.NotifyFailedUpdateIP(err)
 	}
+}
 
-	return nil
+func (m MultiNotifier) NotifySuccessGetIP() {
+	for _, n := range m {
+		n.NotifySuccessGetIP()
+	}
 }
 
-type FakeNotifier struct {
+func (m MultiNotifier) NotifySuccessUpdateIP(ip netip.Addr) {
+	for _, n := range m {
+		n.NotifySuccessUpdateIP(ip)
+	}
 }
 
-func (f FakeNotifier) NotifyFailedGetIP(_ error) {
+// FakeNotifier discards every notification. It's the default when no
+// -notify flags are given.
+type FakeNotifier struct{}
 
+func (f FakeNotifier) NotifyFailedGetIP(_ error) {
 }
 
 func (f FakeNotifier) NotifyFailedUpdateIP(_ error) {
-
 }
 
 func (f FakeNotifier) NotifySuccessGetIP() {
-
 }
 
 func (f FakeNotifier) NotifySuccessUpdateIP(_ netip.Addr) {
-
 }
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DiscordSender posts messages to a Discord channel via an incoming
+// webhook.
+type DiscordSender struct {
+	WebhookURL string
+}
+
+func (d *DiscordSender) Send(level, message string) error {
+	content := message
+	if level == "warning" {
+		content = "⚠️ " + content
+	}
+
+	payload := fmt.Sprintf(`{"content": %q}`, content)
+	resp, err := http.Post(d.WebhookURL, "application/json", strings.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to send discord notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to send discord notification: %s - %s", resp.Status, body)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// GotifySender delivers messages to a self-hosted Gotify server.
+type GotifySender struct {
+	BaseURL string
+	Token   string
+}
+
+func (g *GotifySender) Send(level, message string) error {
+	priority := "5"
+	if level == "warning" {
+		priority = "8"
+	}
+
+	form := url.Values{
+		"title":    {"DNS Updater"},
+		"message":  {message},
+		"priority": {priority},
+	}
+
+	resp, err := http.PostForm(fmt.Sprintf("%s/message?token=%s", g.BaseURL, g.Token), form)
+	if err != nil {
+		return fmt.Errorf("failed to send gotify notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to send gotify notification: %s - %s", resp.Status, body)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// NtfySender delivers messages to a ntfy.sh topic.
+type NtfySender struct {
+	Token string
+}
+
+func (n *NtfySender) Send(level, message string) error {
+	tags := "globe_with_meridians"
+	if level == "warning" {
+		tags = "warning"
+	}
+
+	url := fmt.Sprintf("https://ntfy.sh/%s", n.Token)
+	req, err := http.NewRequest("POST", url, strings.NewReader(message))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", "DNS Updater")
+	req.Header.Set("Tags", tags)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send ntfy notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to send ntfy notification: %s - %s", resp.Status, body)
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// SlackSender posts messages to a Slack channel via an incoming webhook.
+type SlackSender struct {
+	WebhookURL string
+}
+
+func (s *SlackSender) Send(level, message string) error {
+	text := message
+	if level == "warning" {
+		text = ":warning: " + text
+	}
+
+	payload := fmt.Sprintf(`{"text": %q}`, text)
+	resp, err := http.Post(s.WebhookURL, "application/json", strings.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to send slack notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to send slack notification: %s - %s", resp.Status, body)
+	}
+
+	return nil
+}
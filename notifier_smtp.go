@@ -0,0 +1,93 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/smtp"
+)
+
+// SMTPSender delivers messages by email.
+type SMTPSender struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	To       []string
+
+	// ImplicitTLS dials the server over TLS from the start (the classic
+	// smtps:// port-465 convention), instead of connecting in plaintext and
+	// upgrading via STARTTLS.
+	ImplicitTLS bool
+}
+
+func (s *SMTPSender) Send(level, message string) error {
+	subject := "DNS Updater notification"
+	if level == "warning" {
+		subject = "DNS Updater warning"
+	}
+
+	body := fmt.Sprintf("Subject: %s\r\n\r\n%s\r\n", subject, message)
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%s", s.Host, s.Port)
+	if s.ImplicitTLS {
+		if err := s.sendImplicitTLS(addr, auth, body); err != nil {
+			return fmt.Errorf("failed to send smtp notification: %w", err)
+		}
+		return nil
+	}
+
+	if err := smtp.SendMail(addr, auth, s.From, s.To, []byte(body)); err != nil {
+		return fmt.Errorf("failed to send smtp notification: %w", err)
+	}
+
+	return nil
+}
+
+// sendImplicitTLS delivers body over a TLS connection established up front,
+// since net/smtp.SendMail only ever dials in plaintext and upgrades via
+// STARTTLS.
+func (s *SMTPSender) sendImplicitTLS(addr string, auth smtp.Auth, body string) error {
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: s.Host})
+	if err != nil {
+		return fmt.Errorf("failed to dial %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		return fmt.Errorf("failed to start smtp session: %w", err)
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+	if err := client.Mail(s.From); err != nil {
+		return err
+	}
+	for _, to := range s.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(body)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func mustParseAddr(t *testing.T, s string) netip.Addr {
+	t.Helper()
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		t.Fatalf("netip.ParseAddr(%q) failed: %v", s, err)
+	}
+	return addr
+}
+
+// fakeSender records every Send call instead of delivering it anywhere.
+type fakeSender struct {
+	sent []string // one entry per Send call, "level: message"
+}
+
+func (f *fakeSender) Send(level, message string) error {
+	f.sent = append(f.sent, level+": "+message)
+	return nil
+}
+
+func TestGracefulNotifierFailureDedup(t *testing.T) {
+	sender := &fakeSender{}
+	n := NewGracefulNotifier(sender, 50*time.Millisecond)
+
+	n.NotifyFailedGetIP(errors.New("boom"))
+	n.NotifyFailedGetIP(errors.New("boom again"))
+	if len(sender.sent) != 1 {
+		t.Fatalf("got %d sends within grace period, want 1: %v", len(sender.sent), sender.sent)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	n.NotifyFailedGetIP(errors.New("still failing"))
+	if len(sender.sent) != 2 {
+		t.Fatalf("got %d sends after grace period elapsed, want 2: %v", len(sender.sent), sender.sent)
+	}
+}
+
+func TestGracefulNotifierRepairedAfterFailure(t *testing.T) {
+	sender := &fakeSender{}
+	n := NewGracefulNotifier(sender, 50*time.Millisecond)
+
+	n.NotifyFailedUpdateIP(errors.New("boom"))
+	n.NotifySuccessUpdateIP(mustParseAddr(t, "1.2.3.4"))
+
+	if len(sender.sent) != 2 {
+		t.Fatalf("got %d sends, want 2 (failure + repaired): %v", len(sender.sent), sender.sent)
+	}
+	if got := sender.sent[1]; got != "info: Repaired: IP address updated to 1.2.3.4" {
+		t.Errorf("sent[1] = %q, want repaired notification", got)
+	}
+}
+
+func TestGracefulNotifierSuccessWithoutPriorFailure(t *testing.T) {
+	sender := &fakeSender{}
+	n := NewGracefulNotifier(sender, 50*time.Millisecond)
+
+	n.NotifySuccessUpdateIP(mustParseAddr(t, "1.2.3.4"))
+	if len(sender.sent) != 1 {
+		t.Fatalf("got %d sends, want 1: %v", len(sender.sent), sender.sent)
+	}
+	if got := sender.sent[0]; got != "info: New IP address: 1.2.3.4" {
+		t.Errorf("sent[0] = %q, want plain new-address notification", got)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParseNotifier builds a Sender from a notifier URL, shoutrrr-style: the
+// scheme selects the backend, and the rest of the URL carries its
+// configuration.
+//
+//	ntfy://<topic>
+//	discord://<webhook-id>:<webhook-token>@discord   (the host is ignored)
+//	slack://<workspace>/<channel>/<token>   (a Slack incoming webhook path)
+//	gotify://<token>@<host>
+//	smtp(s)://<user>:<pass>@<host>:<port>?to=<addr>&to=<addr>&from=<addr>
+//	http(s)://...?method=<verb>&body=<template>&header=<Key:Value>  (generic webhook)
+func ParseNotifier(rawURL string) (Sender, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notifier URL %q: %w", rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "ntfy":
+		if u.Host == "" {
+			return nil, fmt.Errorf("ntfy notifier URL must be ntfy://<topic>")
+		}
+		return &NtfySender{Token: u.Host}, nil
+
+	case "discord":
+		if u.User == nil {
+			return nil, fmt.Errorf("discord notifier URL must be discord://<webhook-id>:<webhook-token>@discord")
+		}
+		token, _ := u.User.Password()
+		if token == "" {
+			return nil, fmt.Errorf("discord notifier URL must be discord://<webhook-id>:<webhook-token>@discord")
+		}
+		return &DiscordSender{
+			WebhookURL: fmt.Sprintf("https://discord.com/api/webhooks/%s/%s", u.User.Username(), token),
+		}, nil
+
+	case "slack":
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if u.Host == "" || len(parts) != 2 {
+			return nil, fmt.Errorf("slack notifier URL must be slack://<workspace>/<channel>/<token>")
+		}
+		return &SlackSender{
+			WebhookURL: fmt.Sprintf("https://hooks.slack.com/services/%s/%s/%s", u.Host, parts[0], parts[1]),
+		}, nil
+
+	case "gotify":
+		if u.User == nil || u.User.Username() == "" {
+			return nil, fmt.Errorf("gotify notifier URL must be gotify://<token>@<host>")
+		}
+		return &GotifySender{
+			BaseURL: fmt.Sprintf("https://%s", u.Host),
+			Token:   u.User.Username(),
+		}, nil
+
+	case "smtp", "smtps":
+		return parseSMTPNotifier(u)
+
+	case "http", "https":
+		return parseWebhookNotifier(u)
+
+	default:
+		return nil, fmt.Errorf("unsupported notifier scheme %q", u.Scheme)
+	}
+}
+
+// parseWebhookNotifier builds a WebhookSender from a generic http(s) notifier
+// URL. The method, body template and extra headers are passed as query
+// parameters (method, body, header=Key:Value, may repeat) and stripped from
+// the URL before it's used as the request target.
+func parseWebhookNotifier(u *url.URL) (*WebhookSender, error) {
+	query := u.Query()
+
+	method := query.Get("method")
+	query.Del("method")
+
+	body := query.Get("body")
+	query.Del("body")
+
+	var headers map[string]string
+	for _, h := range query["header"] {
+		key, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("webhook notifier header %q must be Key:Value", h)
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	query.Del("header")
+
+	u.RawQuery = query.Encode()
+
+	return &WebhookSender{
+		URL:     u.String(),
+		Method:  method,
+		Headers: headers,
+		Body:    body,
+	}, nil
+}
+
+// parseSMTPNotifier builds an SMTPSender from an smtp:// or smtps:// URL.
+// smtp:// dials in plaintext and upgrades via STARTTLS (the default port is
+// 587); smtps:// dials straight into TLS (the default port is 465), for
+// servers that only support the classic implicit-TLS convention.
+func parseSMTPNotifier(u *url.URL) (*SMTPSender, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("smtp notifier URL must be smtp[s]://[user:pass@]host:port?to=<addr>")
+	}
+
+	implicitTLS := u.Scheme == "smtps"
+	defaultPort := "587"
+	if implicitTLS {
+		defaultPort = "465"
+	}
+
+	host := u.Hostname()
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+
+	query := u.Query()
+	to := query["to"]
+	if len(to) == 0 {
+		return nil, fmt.Errorf("smtp notifier URL must set at least one ?to= recipient")
+	}
+	from := query.Get("from")
+	if from == "" {
+		from = "dyndns@" + host
+	}
+
+	s := &SMTPSender{Host: host, Port: port, From: from, To: to, ImplicitTLS: implicitTLS}
+	if u.User != nil {
+		s.Username = u.User.Username()
+		s.Password, _ = u.User.Password()
+	}
+
+	return s, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WebhookSender POSTs (or otherwise sends) a message to an arbitrary HTTP
+// endpoint. Method, headers and the request body template are all
+// configurable so it can be pointed at services without a dedicated Sender.
+type WebhookSender struct {
+	URL     string
+	Method  string            // defaults to POST
+	Headers map[string]string // extra request headers
+	// Body is a template for the request body; "{{level}}" and
+	// "{{message}}" are replaced with the notification's severity and
+	// text. Defaults to "{{message}}".
+	Body string
+}
+
+func (w *WebhookSender) Send(level, message string) error {
+	method := w.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	body := w.Body
+	if body == "" {
+		body = "{{message}}"
+	}
+	body = strings.NewReplacer("{{level}}", level, "{{message}}", message).Replace(body)
+
+	req, err := http.NewRequest(method, w.URL, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to send webhook notification: %s - %s", resp.Status, respBody)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// Resolver discovers the external IP address from a single configured
+// source.
+type Resolver interface {
+	Resolve() (netip.Addr, error)
+}
+
+// NewResolver builds a Resolver from a source URL.
+//
+//	http://..., https://...        HTTPResolver: GET the URL, body is the plain-text IP
+//	dns://<server>/<name>?type=T   DNSResolver over UDP
+//	dns+tcp://<server>/<name>?type=T  DNSResolver over TCP (RFC 7766)
+//
+// type defaults to "A" and may be "A", "AAAA" or "TXT" (e.g. TXT
+// whoami.cloudflare against 1.1.1.1, or A myip.opendns.com against
+// resolver1.opendns.com).
+func NewResolver(rawURL string) (Resolver, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP source URL %q: %w", rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return &HTTPResolver{URL: rawURL}, nil
+
+	case "dns", "dns+tcp":
+		name := strings.TrimPrefix(u.Path, "/")
+		if u.Host == "" || name == "" {
+			return nil, fmt.Errorf("dns IP source URL must be dns[+tcp]://<server>/<query-name>?type=<A|AAAA|TXT>")
+		}
+		recordType := u.Query().Get("type")
+		if recordType == "" {
+			recordType = "A"
+		}
+		network := "udp"
+		if u.Scheme == "dns+tcp" {
+			network = "tcp"
+		}
+		return &DNSResolver{
+			Server:     withDefaultPort(u.Host, "53"),
+			Network:    network,
+			Name:       name,
+			RecordType: recordType,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported IP source scheme %q", u.Scheme)
+	}
+}
+
+// withDefaultPort appends port to host if host doesn't already specify one.
+func withDefaultPort(host, port string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(host, port)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// DNSResolver discovers the external IP address by querying a DNS server
+// directly, e.g. TXT whoami.cloudflare against 1.1.1.1, A myip.opendns.com
+// against resolver1.opendns.com, or TXT o-o.myaddr.l.google.com against
+// ns1.google.com. This is faster than an HTTP round trip, doesn't
+// rate-limit, and keeps working when HTTP IP-lookup providers are down.
+//
+// Network selects the transport: "udp" (default) or "tcp". TCP (RFC 7766)
+// matters on restrictive networks that block plain DNS, and for IPv6
+// answers that don't fit in a UDP datagram.
+type DNSResolver struct {
+	Server     string // host:port of the DNS server to query
+	Network    string // "udp" or "tcp"
+	Name       string // query name, e.g. "whoami.cloudflare"
+	RecordType string // "A", "AAAA" or "TXT"
+	Timeout    time.Duration
+}
+
+func (r *DNSResolver) Resolve() (netip.Addr, error) {
+	qtype, err := dnsQType(r.RecordType)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	name, err := dnsmessage.NewName(dnsFQDN(r.Name))
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid query name %q: %w", r.Name, err)
+	}
+
+	id, err := randomDNSID()
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("failed to generate DNS query ID: %w", err)
+	}
+
+	query := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  name,
+			Type:  qtype,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("failed to build DNS query: %w", err)
+	}
+
+	timeout := r.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	var raw []byte
+	if r.Network == "tcp" {
+		raw, err = exchangeTCP(r.Server, packed, timeout)
+	} else {
+		raw, err = exchangeUDP(r.Server, packed, timeout)
+	}
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	var resp dnsmessage.Message
+	if err := resp.Unpack(raw); err != nil {
+		return netip.Addr{}, fmt.Errorf("failed to parse DNS response: %w", err)
+	}
+	if resp.Header.ID != id {
+		return netip.Addr{}, fmt.Errorf("DNS response ID mismatch (possible spoofed reply)")
+	}
+
+	return parseDNSAnswer(resp, qtype)
+}
+
+// randomDNSID generates a random 16-bit DNS query ID, so replies can't be
+// spoofed by guessing a predictable one; the answer is trusted straight into
+// production DNS records.
+func randomDNSID() (uint16, error) {
+	var buf [2]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(buf[:]), nil
+}
+
+func dnsQType(recordType string) (dnsmessage.Type, error) {
+	switch recordType {
+	case "A":
+		return dnsmessage.TypeA, nil
+	case "AAAA":
+		return dnsmessage.TypeAAAA, nil
+	case "TXT":
+		return dnsmessage.TypeTXT, nil
+	default:
+		return 0, fmt.Errorf("unsupported DNS record type %q", recordType)
+	}
+}
+
+// dnsFQDN ensures name ends with the trailing dot dnsmessage.NewName requires.
+func dnsFQDN(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// exchangeUDP sends a single datagram and reads the response.
+func exchangeUDP(server string, query []byte, timeout time.Duration) ([]byte, error) {
+	conn, err := net.DialTimeout("udp", server, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", server, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(query); err != nil {
+		return nil, fmt.Errorf("failed to send DNS query: %w", err)
+	}
+
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DNS response: %w", err)
+	}
+	return buf[:n], nil
+}
+
+// exchangeTCP sends the query length-prefixed per RFC 7766 and reads the
+// length-prefixed response.
+func exchangeTCP(server string, query []byte, timeout time.Duration) ([]byte, error) {
+	conn, err := net.DialTimeout("tcp", server, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", server, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	var framed bytes.Buffer
+	if err := binary.Write(&framed, binary.BigEndian, uint16(len(query))); err != nil {
+		return nil, err
+	}
+	framed.Write(query)
+	if _, err := conn.Write(framed.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to send DNS query: %w", err)
+	}
+
+	var length uint16
+	if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+		return nil, fmt.Errorf("failed to read DNS response length: %w", err)
+	}
+	resp := make([]byte, length)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, fmt.Errorf("failed to read DNS response: %w", err)
+	}
+	return resp, nil
+}
+
+// parseDNSAnswer extracts the IP address from the first answer matching
+// qtype in msg.
+func parseDNSAnswer(msg dnsmessage.Message, qtype dnsmessage.Type) (netip.Addr, error) {
+	for _, a := range msg.Answers {
+		if a.Header.Type != qtype {
+			continue
+		}
+		switch body := a.Body.(type) {
+		case *dnsmessage.AResource:
+			return netip.AddrFrom4(body.A), nil
+		case *dnsmessage.AAAAResource:
+			return netip.AddrFrom16(body.AAAA), nil
+		case *dnsmessage.TXTResource:
+			for _, txt := range body.TXT {
+				if ip, err := netip.ParseAddr(strings.Trim(txt, `"`)); err == nil {
+					return ip, nil
+				}
+			}
+		}
+	}
+	return netip.Addr{}, fmt.Errorf("no %s answer found in DNS response", qtype)
+}
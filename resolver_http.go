@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// HTTPResolver discovers the external IP address by GETting a URL whose
+// response body is the plain-text IP, e.g. https://api.ipify.org.
+type HTTPResolver struct {
+	URL string
+}
+
+func (r *HTTPResolver) Resolve() (netip.Addr, error) {
+	resp, err := http.Get(r.URL)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	ipStr := strings.TrimSpace(string(body))
+	ip, err := netip.ParseAddr(ipStr)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid IP address format: %s", ipStr)
+	}
+	return ip, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import "testing"
+
+func TestNewResolver(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{"http", "http://api.ipify.org", false},
+		{"https", "https://api.ipify.org", false},
+		{"dns", "dns://1.1.1.1/whoami.cloudflare?type=TXT", false},
+		{"dns with port", "dns://1.1.1.1:5353/whoami.cloudflare", false},
+		{"dns+tcp", "dns+tcp://resolver1.opendns.com/myip.opendns.com?type=A", false},
+		{"dns missing name", "dns://1.1.1.1", true},
+		{"dns missing host", "dns:///myip.opendns.com", true},
+		{"unsupported scheme", "ftp://example.com", true},
+		{"invalid URL", "://bad", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewResolver(tt.url)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NewResolver(%q) = %v, want error", tt.url, r)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewResolver(%q) failed: %v", tt.url, err)
+			}
+			if r == nil {
+				t.Fatalf("NewResolver(%q) = nil, nil", tt.url)
+			}
+		})
+	}
+}
+
+func TestNewResolverDNSDefaults(t *testing.T) {
+	r, err := NewResolver("dns://1.1.1.1/whoami.cloudflare")
+	if err != nil {
+		t.Fatalf("NewResolver() failed: %v", err)
+	}
+	dr, ok := r.(*DNSResolver)
+	if !ok {
+		t.Fatalf("NewResolver() = %T, want *DNSResolver", r)
+	}
+	if dr.RecordType != "A" {
+		t.Errorf("RecordType = %q, want %q", dr.RecordType, "A")
+	}
+	if dr.Network != "udp" {
+		t.Errorf("Network = %q, want %q", dr.Network, "udp")
+	}
+	if dr.Server != "1.1.1.1:53" {
+		t.Errorf("Server = %q, want %q", dr.Server, "1.1.1.1:53")
+	}
+}
+
+func TestNewResolverDNSTCP(t *testing.T) {
+	r, err := NewResolver("dns+tcp://1.1.1.1/whoami.cloudflare")
+	if err != nil {
+		t.Fatalf("NewResolver() failed: %v", err)
+	}
+	dr := r.(*DNSResolver)
+	if dr.Network != "tcp" {
+		t.Errorf("Network = %q, want %q", dr.Network, "tcp")
+	}
+}
@@ -1,14 +1,11 @@
 package main
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	log "log/slog"
 	"net/http"
 	"net/netip"
-	"os"
-
-	cloudflare "github.com/cloudflare/cloudflare-go"
 )
 
 type Server struct {
@@ -38,11 +35,13 @@ func (s *Server) HandleIndex(w http.ResponseWriter, r *http.Request) {
 	log.Info("New request.", "method", r.Method, "url", r.URL, "agent", r.Header["User-Agent"])
 	query := r.URL.Query()
 
-	zone, ok := query["zone"]
+	nameQuery, ok := query["name"]
 	if !ok {
-		httpError(w, http.StatusBadRequest, "missing zone parameter in query")
+		httpError(w, http.StatusBadRequest, "missing name parameter in query")
 		return
 	}
+	name := nameQuery[0]
+	zone := query.Get("zone")
 
 	ipv4Query, ok := query["ip"]
 	if !ok {
@@ -56,8 +55,24 @@ func (s *Server) HandleIndex(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = s.updater.UpdateIP4(addr, zone[0])
-	if err != nil {
+	var records []RecordConfig
+	for _, rec := range s.updater.MatchingRecords(zone, name) {
+		if rec.Type == "A" {
+			records = append(records, rec)
+		}
+	}
+	if len(records) == 0 {
+		httpError(w, http.StatusNotFound, fmt.Sprintf("no configured A record named %s", name))
+		return
+	}
+
+	var errs []error
+	for _, rec := range records {
+		if err := s.updater.Update(rec, addr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
 		httpError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -85,49 +100,3 @@ func httpSuccess(w http.ResponseWriter, status int, message string) {
 		log.Warn("Failed to write response.", "error", err)
 	}
 }
-
-// updateRecord updates a DNS record with the given IP address and record type. Use "A" for IPv4 and "AAAA" for IPv6.
-func updateRecord(zoneName string, ip string, recordType string) error {
-	api, err := cloudflare.NewWithAPIToken(os.Getenv("CLOUDFLARE_API_TOKEN"))
-	if err != nil {
-		return err
-	}
-
-	zoneID, err := api.ZoneIDByName(zoneName)
-	if err != nil {
-		return fmt.Errorf("failed to find zone %s: %w", zoneName, err)
-	}
-	id, err := findDNSRecordID(api, zoneID, recordType)
-	if err != nil {
-		return fmt.Errorf("failed to find %s record for zone %s: %w", recordType, zoneName, err)
-	}
-	updateParams := cloudflare.UpdateDNSRecordParams{
-		Content: ip,
-		Type:    recordType,
-		ID:      id,
-	}
-	rec, err := api.UpdateDNSRecord(context.Background(), cloudflare.ZoneIdentifier(zoneID), updateParams)
-	if err != nil {
-		return fmt.Errorf("failed to update %s record for zone %s: %w", recordType, zoneName, err)
-	}
-	log.Info("Record updated successfully.", "recordType", rec.Type, "content", rec.Content, "zone", zoneName)
-
-	return nil
-}
-
-// findDNSRecordID finds the ID of the first DNS record matching the specified type.
-func findDNSRecordID(api *cloudflare.API, zoneID string, recordType string) (string, error) {
-	recs, _, err := api.ListDNSRecords(context.Background(), cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{Type: recordType})
-	if err != nil {
-		return "", err
-	}
-
-	// Since we filter by Type in the API call, the first record found should be the correct one.
-	// Cloudflare typically only allows one A or AAAA record for the root zone name unless using load balancing etc.
-	if len(recs) > 0 {
-		log.Debug("Found DNS record", "type", recordType, "id", recs[0].ID, "content", recs[0].Content)
-		return recs[0].ID, nil
-	}
-
-	return "", fmt.Errorf("no %s record found", recordType)
-}